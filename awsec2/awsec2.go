@@ -0,0 +1,262 @@
+// Package awsec2 はEC2 APIを呼び出す薄いラッパー群
+package awsec2
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+
+	"github.com/takumi3488/ec2dev/config"
+)
+
+type EC2DescribeInstancesAPI interface {
+	DescribeInstances(ctx context.Context,
+		params *ec2.DescribeInstancesInput,
+		optFns ...func(*ec2.Options)) (*ec2.DescribeInstancesOutput, error)
+}
+
+type EC2StartInstancesAPI interface {
+	StartInstances(ctx context.Context,
+		params *ec2.StartInstancesInput,
+		optFns ...func(*ec2.Options)) (*ec2.StartInstancesOutput, error)
+}
+
+type EC2StopInstancesAPI interface {
+	StopInstances(ctx context.Context,
+		params *ec2.StopInstancesInput,
+		optFns ...func(*ec2.Options)) (*ec2.StopInstancesOutput, error)
+}
+
+type EC2RunInstancesAPI interface {
+	RunInstances(ctx context.Context,
+		params *ec2.RunInstancesInput,
+		optFns ...func(*ec2.Options)) (*ec2.RunInstancesOutput, error)
+}
+
+type EC2CreateTagsAPI interface {
+	CreateTags(ctx context.Context,
+		params *ec2.CreateTagsInput,
+		optFns ...func(*ec2.Options)) (*ec2.CreateTagsOutput, error)
+}
+
+type launchAPI interface {
+	EC2RunInstancesAPI
+	EC2DescribeInstancesAPI
+	EC2CreateTagsAPI
+}
+
+// NewClient はprofileのRegion・AWSProfile・AssumeRoleARNを反映したEC2クライアントを作成する
+func NewClient(ctx context.Context, profile config.Profile) (*ec2.Client, error) {
+	var opts []func(*awsconfig.LoadOptions) error
+	if profile.AWSProfile != "" {
+		opts = append(opts, awsconfig.WithSharedConfigProfile(profile.AWSProfile))
+	}
+
+	// AWS CLIのデフォルト設定(または指定されたAWSProfile)を読み込み
+	cfg, err := awsconfig.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("configuration error, %w", err)
+	}
+
+	// Regionが設定されていればデフォルトから上書き
+	if profile.Region != "" {
+		cfg.Region = profile.Region
+	}
+
+	// AssumeRoleARNが設定されていれば、それをAssumeしたクレデンシャルに差し替える
+	if profile.AssumeRoleARN != "" {
+		stsClient := sts.NewFromConfig(cfg)
+		provider := stscreds.NewAssumeRoleProvider(stsClient, profile.AssumeRoleARN, func(o *stscreds.AssumeRoleOptions) {
+			if profile.MFASerial != "" {
+				o.SerialNumber = &profile.MFASerial
+				o.TokenProvider = stscreds.StdinTokenProvider
+			}
+		})
+		cfg.Credentials = aws.NewCredentialsCache(provider)
+	}
+
+	return ec2.NewFromConfig(cfg), nil
+}
+
+// instanceIDのインスタンスを取得
+func GetInstances(c context.Context, api EC2DescribeInstancesAPI, instanceID string) (types.Instance, error) {
+	describeInstancesInput := &ec2.DescribeInstancesInput{InstanceIds: []string{instanceID}}
+	res, err := api.DescribeInstances(c, describeInstancesInput)
+	if err != nil {
+		return types.Instance{}, fmt.Errorf("got an error describing the instance: %w", err)
+	}
+	if len(res.Reservations) == 0 || len(res.Reservations[0].Instances) == 0 {
+		return types.Instance{}, errors.New("no instances found")
+	}
+	instance := res.Reservations[0].Instances[0]
+	return instance, nil
+}
+
+// ListInstances はfiltersに合致するインスタンス一覧をページングしながら取得する
+func ListInstances(ctx context.Context, api EC2DescribeInstancesAPI, filters []types.Filter) ([]types.Instance, error) {
+	var instances []types.Instance
+	paginator := ec2.NewDescribeInstancesPaginator(api, &ec2.DescribeInstancesInput{Filters: filters})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("got an error listing instances: %w", err)
+		}
+		for _, r := range page.Reservations {
+			instances = append(instances, r.Instances...)
+		}
+	}
+	return instances, nil
+}
+
+// インスタンスを開始
+func StartInstance(c context.Context, api EC2StartInstancesAPI, instanceID string) {
+	input := &ec2.StartInstancesInput{
+		InstanceIds: []string{
+			instanceID,
+		},
+	}
+	res, err := api.StartInstances(c, input)
+	if err != nil {
+		fmt.Println("Got an error starting the instance")
+		fmt.Println(err)
+		return
+	}
+	OutputChangedInstance(res.StartingInstances[0])
+}
+
+// インスタンスを停止
+func StopInstance(c context.Context, api EC2StopInstancesAPI, instanceID string) {
+	input := &ec2.StopInstancesInput{
+		InstanceIds: []string{
+			instanceID,
+		},
+	}
+	res, err := api.StopInstances(c, input)
+	if err != nil {
+		fmt.Println("Got an error stopping the instance")
+		fmt.Println(err)
+		return
+	}
+	OutputChangedInstance(res.StoppingInstances[0])
+}
+
+// WaitForState はインスタンスがtargetの状態になるまでSDKのwaiterを使って待つ。
+// timeoutを過ぎても到達しなければエラーを返す
+func WaitForState(ctx context.Context, client ec2.DescribeInstancesAPIClient, instanceID string, target types.InstanceStateName, timeout time.Duration) error {
+	fmt.Fprintf(os.Stderr, "Waiting for %s state (timeout %s).\n", target, timeout)
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(15 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				fmt.Fprintf(os.Stderr, "Still waiting for %s state...\n", target)
+			}
+		}
+	}()
+
+	input := &ec2.DescribeInstancesInput{InstanceIds: []string{instanceID}}
+	var err error
+	switch target {
+	case types.InstanceStateNameRunning:
+		err = ec2.NewInstanceRunningWaiter(client).Wait(ctx, input, timeout)
+	case types.InstanceStateNameStopped:
+		err = ec2.NewInstanceStoppedWaiter(client).Wait(ctx, input, timeout)
+	default:
+		return fmt.Errorf("unsupported wait target state: %s", target)
+	}
+	if err != nil {
+		return fmt.Errorf("timed out waiting for %s state: %w", target, err)
+	}
+	return nil
+}
+
+// 変更されたインスタンスIDと状態を出力
+func OutputChangedInstance(instance types.InstanceStateChange) {
+	fmt.Printf("The instance state has been successfully changed!\nInstance ID: %s\nState: %s\n", *instance.InstanceId, instance.CurrentState.Name)
+}
+
+// インスタンスを新規作成し、PublicIpAddressが付与されるまで待つ
+func LaunchInstance(c context.Context, api launchAPI, profile config.Profile) (types.Instance, error) {
+	blockDeviceMappings := make([]types.BlockDeviceMapping, 0, len(profile.BlockDeviceMappings))
+	for _, b := range profile.BlockDeviceMappings {
+		b := b
+		blockDeviceMappings = append(blockDeviceMappings, types.BlockDeviceMapping{
+			DeviceName: &b.DeviceName,
+			Ebs: &types.EbsBlockDevice{
+				VolumeSize: &b.VolumeSize,
+				VolumeType: types.VolumeType(b.VolumeType),
+			},
+		})
+	}
+
+	var userData *string
+	if profile.UserDataFile != "" {
+		raw, err := ioutil.ReadFile(profile.UserDataFile)
+		if err != nil {
+			return types.Instance{}, fmt.Errorf("failed to read user_data_file: %w", err)
+		}
+		encoded := base64.StdEncoding.EncodeToString(raw)
+		userData = &encoded
+	}
+
+	runInput := &ec2.RunInstancesInput{
+		ImageId:          &profile.AMI,
+		InstanceType:     types.InstanceType(profile.InstanceType),
+		MinCount:         aws.Int32(1),
+		MaxCount:         aws.Int32(1),
+		SubnetId:         &profile.Subnet,
+		SecurityGroupIds: []string{profile.SecurityGroup},
+		KeyName:          &profile.KeyPair,
+		IamInstanceProfile: &types.IamInstanceProfileSpecification{
+			Name: &profile.IAMInstanceProfile,
+		},
+		BlockDeviceMappings: blockDeviceMappings,
+		UserData:            userData,
+	}
+
+	runRes, err := api.RunInstances(c, runInput)
+	if err != nil {
+		return types.Instance{}, fmt.Errorf("got an error launching the instance: %w", err)
+	}
+	instanceID := *runRes.Instances[0].InstanceId
+
+	if _, err := api.CreateTags(c, &ec2.CreateTagsInput{
+		Resources: []string{instanceID},
+		Tags:      []types.Tag{{Key: aws.String("Name"), Value: &profile.Name}},
+	}); err != nil {
+		return types.Instance{}, fmt.Errorf("got an error tagging the instance: %w", err)
+	}
+
+	// 起動直後はPublicIpAddressが空で返ってくるため、付与されるまでポーリングする
+	fmt.Println("Waiting for a public IP address to be assigned.")
+	var instance types.Instance
+	for i := 0; i < 60; i++ {
+		instance, err = GetInstances(c, api, instanceID)
+		if err != nil {
+			return types.Instance{}, err
+		}
+		if instance.PublicIpAddress != nil {
+			return instance, nil
+		}
+		time.Sleep(time.Second * 2)
+	}
+
+	return types.Instance{}, errors.New("timed out waiting for a public IP address")
+}
@@ -0,0 +1,215 @@
+package awsec2
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+
+	"github.com/takumi3488/ec2dev/config"
+)
+
+type mockDescribeInstancesAPI func(ctx context.Context, params *ec2.DescribeInstancesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeInstancesOutput, error)
+
+func (m mockDescribeInstancesAPI) DescribeInstances(ctx context.Context, params *ec2.DescribeInstancesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeInstancesOutput, error) {
+	return m(ctx, params, optFns...)
+}
+
+type mockLaunchAPI struct {
+	runInstances      func(ctx context.Context, params *ec2.RunInstancesInput, optFns ...func(*ec2.Options)) (*ec2.RunInstancesOutput, error)
+	describeInstances func(ctx context.Context, params *ec2.DescribeInstancesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeInstancesOutput, error)
+	createTags        func(ctx context.Context, params *ec2.CreateTagsInput, optFns ...func(*ec2.Options)) (*ec2.CreateTagsOutput, error)
+}
+
+func (m mockLaunchAPI) RunInstances(ctx context.Context, params *ec2.RunInstancesInput, optFns ...func(*ec2.Options)) (*ec2.RunInstancesOutput, error) {
+	return m.runInstances(ctx, params, optFns...)
+}
+
+func (m mockLaunchAPI) DescribeInstances(ctx context.Context, params *ec2.DescribeInstancesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeInstancesOutput, error) {
+	return m.describeInstances(ctx, params, optFns...)
+}
+
+func (m mockLaunchAPI) CreateTags(ctx context.Context, params *ec2.CreateTagsInput, optFns ...func(*ec2.Options)) (*ec2.CreateTagsOutput, error) {
+	return m.createTags(ctx, params, optFns...)
+}
+
+func TestGetInstances(t *testing.T) {
+	instanceID := "i-0123456789"
+	api := mockDescribeInstancesAPI(func(ctx context.Context, params *ec2.DescribeInstancesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeInstancesOutput, error) {
+		if len(params.InstanceIds) != 1 || params.InstanceIds[0] != instanceID {
+			t.Fatalf("unexpected InstanceIds: %v", params.InstanceIds)
+		}
+		return &ec2.DescribeInstancesOutput{
+			Reservations: []types.Reservation{
+				{Instances: []types.Instance{{InstanceId: &instanceID}}},
+			},
+		}, nil
+	})
+
+	instance, err := GetInstances(context.TODO(), api, instanceID)
+	if err != nil {
+		t.Fatalf("GetInstances returned error: %v", err)
+	}
+	if *instance.InstanceId != instanceID {
+		t.Fatalf("InstanceId = %q, want %q", *instance.InstanceId, instanceID)
+	}
+}
+
+func TestGetInstancesNotFound(t *testing.T) {
+	api := mockDescribeInstancesAPI(func(ctx context.Context, params *ec2.DescribeInstancesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeInstancesOutput, error) {
+		return &ec2.DescribeInstancesOutput{}, nil
+	})
+
+	if _, err := GetInstances(context.TODO(), api, "i-missing"); err == nil {
+		t.Fatal("GetInstances should return an error when no instances are found")
+	}
+}
+
+func TestGetInstancesAPIError(t *testing.T) {
+	api := mockDescribeInstancesAPI(func(ctx context.Context, params *ec2.DescribeInstancesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeInstancesOutput, error) {
+		return nil, errors.New("throttled")
+	})
+
+	if _, err := GetInstances(context.TODO(), api, "i-0123456789"); err == nil {
+		t.Fatal("GetInstances should return the underlying API error instead of panicking")
+	}
+}
+
+func TestListInstancesPaginates(t *testing.T) {
+	pages := []*ec2.DescribeInstancesOutput{
+		{
+			Reservations: []types.Reservation{{Instances: []types.Instance{{InstanceId: aws.String("i-1")}}}},
+			NextToken:    aws.String("page2"),
+		},
+		{
+			Reservations: []types.Reservation{{Instances: []types.Instance{{InstanceId: aws.String("i-2")}}}},
+		},
+	}
+	calls := 0
+	api := mockDescribeInstancesAPI(func(ctx context.Context, params *ec2.DescribeInstancesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeInstancesOutput, error) {
+		page := pages[calls]
+		calls++
+		return page, nil
+	})
+
+	instances, err := ListInstances(context.TODO(), api, nil)
+	if err != nil {
+		t.Fatalf("ListInstances returned error: %v", err)
+	}
+	if len(instances) != 2 {
+		t.Fatalf("got %d instances, want 2", len(instances))
+	}
+	if *instances[0].InstanceId != "i-1" || *instances[1].InstanceId != "i-2" {
+		t.Fatalf("unexpected instances: %v", instances)
+	}
+}
+
+func TestListInstancesError(t *testing.T) {
+	api := mockDescribeInstancesAPI(func(ctx context.Context, params *ec2.DescribeInstancesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeInstancesOutput, error) {
+		return nil, errors.New("boom")
+	})
+
+	if _, err := ListInstances(context.TODO(), api, nil); err == nil {
+		t.Fatal("ListInstances should return an error when a page fails")
+	}
+}
+
+func TestLaunchInstanceWaitsForPublicIP(t *testing.T) {
+	instanceID := "i-new"
+	describeCalls := 0
+	api := mockLaunchAPI{
+		runInstances: func(ctx context.Context, params *ec2.RunInstancesInput, optFns ...func(*ec2.Options)) (*ec2.RunInstancesOutput, error) {
+			return &ec2.RunInstancesOutput{Instances: []types.Instance{{InstanceId: &instanceID}}}, nil
+		},
+		describeInstances: func(ctx context.Context, params *ec2.DescribeInstancesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeInstancesOutput, error) {
+			describeCalls++
+			instance := types.Instance{InstanceId: &instanceID}
+			if describeCalls > 1 {
+				instance.PublicIpAddress = aws.String("1.2.3.4")
+			}
+			return &ec2.DescribeInstancesOutput{Reservations: []types.Reservation{{Instances: []types.Instance{instance}}}}, nil
+		},
+		createTags: func(ctx context.Context, params *ec2.CreateTagsInput, optFns ...func(*ec2.Options)) (*ec2.CreateTagsOutput, error) {
+			return &ec2.CreateTagsOutput{}, nil
+		},
+	}
+
+	profile := config.Profile{AMI: "ami-1", InstanceType: "t3.micro", Subnet: "subnet-1", SecurityGroup: "sg-1", KeyPair: "key", Name: "dev"}
+	instance, err := LaunchInstance(context.TODO(), api, profile)
+	if err != nil {
+		t.Fatalf("LaunchInstance returned error: %v", err)
+	}
+	if aws.ToString(instance.PublicIpAddress) != "1.2.3.4" {
+		t.Fatalf("PublicIpAddress = %q, want 1.2.3.4", aws.ToString(instance.PublicIpAddress))
+	}
+}
+
+func TestLaunchInstancePropagatesDescribeError(t *testing.T) {
+	instanceID := "i-new"
+	api := mockLaunchAPI{
+		runInstances: func(ctx context.Context, params *ec2.RunInstancesInput, optFns ...func(*ec2.Options)) (*ec2.RunInstancesOutput, error) {
+			return &ec2.RunInstancesOutput{Instances: []types.Instance{{InstanceId: &instanceID}}}, nil
+		},
+		describeInstances: func(ctx context.Context, params *ec2.DescribeInstancesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeInstancesOutput, error) {
+			return nil, errors.New("throttled")
+		},
+		createTags: func(ctx context.Context, params *ec2.CreateTagsInput, optFns ...func(*ec2.Options)) (*ec2.CreateTagsOutput, error) {
+			return &ec2.CreateTagsOutput{}, nil
+		},
+	}
+
+	profile := config.Profile{AMI: "ami-1", InstanceType: "t3.micro", Subnet: "subnet-1", SecurityGroup: "sg-1", KeyPair: "key", Name: "dev"}
+	if _, err := LaunchInstance(context.TODO(), api, profile); err == nil {
+		t.Fatal("LaunchInstance should return the DescribeInstances error instead of looping forever or panicking")
+	}
+}
+
+func TestWaitForStateReturnsOnTargetState(t *testing.T) {
+	instanceID := "i-0123456789"
+	api := mockDescribeInstancesAPI(func(ctx context.Context, params *ec2.DescribeInstancesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeInstancesOutput, error) {
+		return &ec2.DescribeInstancesOutput{
+			Reservations: []types.Reservation{{Instances: []types.Instance{{
+				InstanceId: &instanceID,
+				State:      &types.InstanceState{Name: types.InstanceStateNameRunning},
+			}}}},
+		}, nil
+	})
+
+	if err := WaitForState(context.TODO(), api, instanceID, types.InstanceStateNameRunning, 5*time.Second); err != nil {
+		t.Fatalf("WaitForState returned error: %v", err)
+	}
+}
+
+func TestWaitForStateUnsupportedTarget(t *testing.T) {
+	api := mockDescribeInstancesAPI(func(ctx context.Context, params *ec2.DescribeInstancesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeInstancesOutput, error) {
+		return &ec2.DescribeInstancesOutput{}, nil
+	})
+
+	if err := WaitForState(context.TODO(), api, "i-0123456789", types.InstanceStateNameTerminated, time.Second); err == nil {
+		t.Fatal("WaitForState should reject a state it has no waiter for")
+	}
+}
+
+func TestNewClientAppliesRegionOverride(t *testing.T) {
+	client, err := NewClient(context.TODO(), config.Profile{Region: "ap-northeast-1"})
+	if err != nil {
+		t.Fatalf("NewClient returned error: %v", err)
+	}
+	if got := client.Options().Region; got != "ap-northeast-1" {
+		t.Fatalf("Region = %q, want ap-northeast-1", got)
+	}
+}
+
+func TestNewClientWithAssumeRole(t *testing.T) {
+	client, err := NewClient(context.TODO(), config.Profile{Region: "us-east-1", AssumeRoleARN: "arn:aws:iam::123456789012:role/dev"})
+	if err != nil {
+		t.Fatalf("NewClient returned error: %v", err)
+	}
+	if client.Options().Credentials == nil {
+		t.Fatal("expected AssumeRoleARN to produce a non-nil credentials provider")
+	}
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/takumi3488/ec2dev/awsec2"
+	"github.com/takumi3488/ec2dev/config"
+)
+
+var launchCommand = &cli.Command{
+	Name:   "launch",
+	Usage:  "provision a new EC2 development instance from config.yml",
+	Action: launchAction,
+}
+
+// launchAction はconfig.ymlの設定をもとに新規にEC2インスタンスを作成し、
+// そのInstanceIDをconfig.ymlに書き戻す
+func launchAction(ctx *cli.Context) error {
+	home := os.Getenv("HOME")
+
+	cfg, err := config.Load(home)
+	if err != nil {
+		panic(err)
+	}
+	name, profile, err := cfg.Resolve(ctx.String("profile"))
+	if err != nil {
+		return err
+	}
+
+	client, err := awsec2.NewClient(context.TODO(), profile)
+	if err != nil {
+		panic(err)
+	}
+
+	instance, err := awsec2.LaunchInstance(context.TODO(), client, profile)
+	if err != nil {
+		return err
+	}
+
+	profile.InstanceID = *instance.InstanceId
+	cfg.Set(name, profile)
+	if err := config.Save(home, cfg); err != nil {
+		return fmt.Errorf("failed to write instance id back to config.yml: %w", err)
+	}
+
+	fmt.Printf("Instance launched.\nInstance ID: %s\nPublic IP: %s\n", *instance.InstanceId, *instance.PublicIpAddress)
+	return nil
+}
@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/olekukonko/tablewriter"
+	"github.com/urfave/cli/v2"
+
+	"github.com/takumi3488/ec2dev/awsec2"
+	"github.com/takumi3488/ec2dev/config"
+)
+
+var listCommand = &cli.Command{
+	Name:    "list",
+	Aliases: []string{"ls"},
+	Usage:   "list EC2 instances visible to the configured credentials",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "region",
+			Usage: "AWS region to list instances in (overrides the profile's region)",
+		},
+		&cli.StringFlag{
+			Name:  "state",
+			Value: "all",
+			Usage: "filter by instance state: running, stopped, or all",
+		},
+		&cli.StringSliceFlag{
+			Name:  "tag",
+			Usage: "filter by tag, in key=value form (may be repeated)",
+		},
+	},
+	Action: listAction,
+}
+
+func listAction(ctx *cli.Context) error {
+	home := os.Getenv("HOME")
+
+	cfg, err := config.Load(home)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	profile, err := resolveListProfile(cfg, ctx.String("profile"))
+	if err != nil {
+		return err
+	}
+	if region := ctx.String("region"); region != "" {
+		profile.Region = region
+	}
+
+	client, err := awsec2.NewClient(context.TODO(), profile)
+	if err != nil {
+		return fmt.Errorf("failed to create EC2 client: %w", err)
+	}
+
+	filters, err := buildListFilters(ctx.String("state"), ctx.StringSlice("tag"))
+	if err != nil {
+		return err
+	}
+
+	instances, err := awsec2.ListInstances(context.TODO(), client, filters)
+	if err != nil {
+		return err
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Name", "Instance ID", "Type", "AZ", "Private IP", "Public IP", "State", "Launch Time"})
+	for _, i := range instances {
+		table.Append([]string{
+			instanceName(i),
+			aws.ToString(i.InstanceId),
+			string(i.InstanceType),
+			availabilityZone(i),
+			aws.ToString(i.PrivateIpAddress),
+			aws.ToString(i.PublicIpAddress),
+			string(i.State.Name),
+			launchTime(i),
+		})
+	}
+	table.Render()
+
+	return nil
+}
+
+func instanceName(i types.Instance) string {
+	for _, t := range i.Tags {
+		if aws.ToString(t.Key) == "Name" {
+			return aws.ToString(t.Value)
+		}
+	}
+	return ""
+}
+
+func availabilityZone(i types.Instance) string {
+	if i.Placement == nil {
+		return ""
+	}
+	return aws.ToString(i.Placement.AvailabilityZone)
+}
+
+func launchTime(i types.Instance) string {
+	if i.LaunchTime == nil {
+		return ""
+	}
+	return i.LaunchTime.Format(time.RFC3339)
+}
+
+// resolveListProfile resolves the profile ls should use. A missing config
+// file or an absent default profile falls back to an empty profile rather
+// than blocking discovery on a fully configured ~/.ec2dev/config.yml, but an
+// explicit --profile that doesn't resolve is still a real error.
+func resolveListProfile(cfg config.Config, requested string) (config.Profile, error) {
+	if requested != "" {
+		_, profile, err := cfg.Resolve(requested)
+		if err != nil {
+			return config.Profile{}, err
+		}
+		return profile, nil
+	}
+	if _, profile, err := cfg.Resolve(""); err == nil {
+		return profile, nil
+	}
+	return config.Profile{}, nil
+}
+
+func buildListFilters(state string, tags []string) ([]types.Filter, error) {
+	var filters []types.Filter
+	switch state {
+	case "all":
+	case "running", "stopped":
+		filters = append(filters, types.Filter{Name: aws.String("instance-state-name"), Values: []string{state}})
+	default:
+		return nil, fmt.Errorf("invalid --state %q: must be running, stopped, or all", state)
+	}
+
+	for _, tag := range tags {
+		kv := strings.SplitN(tag, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid --tag %q: must be in key=value form", tag)
+		}
+		filters = append(filters, types.Filter{Name: aws.String("tag:" + kv[0]), Values: []string{kv[1]}})
+	}
+
+	return filters, nil
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/takumi3488/ec2dev/config"
+)
+
+func TestResolveListProfileFallsBackWhenNoDefault(t *testing.T) {
+	profile, err := resolveListProfile(config.Config{}, "")
+	if err != nil {
+		t.Fatalf("resolveListProfile returned error: %v", err)
+	}
+	if !reflect.DeepEqual(profile, config.Profile{}) {
+		t.Fatalf("profile = %+v, want zero value", profile)
+	}
+}
+
+func TestResolveListProfileUsesDefault(t *testing.T) {
+	cfg := config.Config{
+		Default:  "dev",
+		Profiles: map[string]config.Profile{"dev": {Region: "us-east-1"}},
+	}
+
+	profile, err := resolveListProfile(cfg, "")
+	if err != nil {
+		t.Fatalf("resolveListProfile returned error: %v", err)
+	}
+	if profile.Region != "us-east-1" {
+		t.Fatalf("Region = %q, want us-east-1", profile.Region)
+	}
+}
+
+func TestResolveListProfileRejectsUnknownExplicitProfile(t *testing.T) {
+	cfg := config.Config{Profiles: map[string]config.Profile{"dev": {Region: "us-east-1"}}}
+
+	if _, err := resolveListProfile(cfg, "prod-typo"); err == nil {
+		t.Fatal("resolveListProfile should reject an explicit --profile that doesn't exist, not fall back silently")
+	}
+}
+
+func TestBuildListFiltersState(t *testing.T) {
+	filters, err := buildListFilters("all", nil)
+	if err != nil {
+		t.Fatalf("buildListFilters returned error: %v", err)
+	}
+	if len(filters) != 0 {
+		t.Fatalf("state=all should add no filters, got %+v", filters)
+	}
+
+	filters, err = buildListFilters("running", nil)
+	if err != nil {
+		t.Fatalf("buildListFilters returned error: %v", err)
+	}
+	if len(filters) != 1 || *filters[0].Name != "instance-state-name" || filters[0].Values[0] != "running" {
+		t.Fatalf("unexpected filters for state=running: %+v", filters)
+	}
+
+	if _, err := buildListFilters("bogus", nil); err == nil {
+		t.Fatal("buildListFilters should reject an invalid --state value")
+	}
+}
+
+func TestBuildListFiltersTag(t *testing.T) {
+	filters, err := buildListFilters("all", []string{"env=dev"})
+	if err != nil {
+		t.Fatalf("buildListFilters returned error: %v", err)
+	}
+	if len(filters) != 1 || *filters[0].Name != "tag:env" || filters[0].Values[0] != "dev" {
+		t.Fatalf("unexpected filters for --tag env=dev: %+v", filters)
+	}
+
+	if _, err := buildListFilters("all", []string{"no-equals-sign"}); err == nil {
+		t.Fatal("buildListFilters should reject a malformed --tag value")
+	}
+}
@@ -0,0 +1,28 @@
+// Package cmd はec2devのCLIエントリポイントを構成する
+package cmd
+
+import (
+	"github.com/urfave/cli/v2"
+)
+
+// Execute はCLIアプリを構築して実行する
+func Execute(args []string) error {
+	app := &cli.App{
+		Name:  "ec2dev",
+		Usage: "start, stop and connect to an EC2 development instance",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "profile",
+				Aliases: []string{"p"},
+				Usage:   "name of the profile to use (defaults to the `default` key in config.yml)",
+			},
+		},
+		Action: toggleAction,
+		Commands: []*cli.Command{
+			launchCommand,
+			listCommand,
+		},
+	}
+
+	return app.Run(args)
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/urfave/cli/v2"
+
+	"github.com/takumi3488/ec2dev/awsec2"
+	"github.com/takumi3488/ec2dev/config"
+	"github.com/takumi3488/ec2dev/sshconfig"
+)
+
+// toggleAction は起動中のインスタンスを停止、停止中のインスタンスを起動する
+func toggleAction(ctx *cli.Context) error {
+	home := os.Getenv("HOME")
+	sc := bufio.NewScanner(os.Stdin)
+
+	cfg, err := config.Load(home)
+	if err != nil {
+		panic(err)
+	}
+	_, profile, err := cfg.Resolve(ctx.String("profile"))
+	if err != nil {
+		return err
+	}
+
+	// インスタンスIDが無ければ終了
+	if profile.InstanceID == "" {
+		fmt.Println("You must supply an instance ID")
+		return nil
+	}
+
+	client, err := awsec2.NewClient(context.TODO(), profile)
+	if err != nil {
+		panic(err)
+	}
+
+	// インスタンスを取得
+	instance, err := awsec2.GetInstances(context.TODO(), client, profile.InstanceID)
+	if err != nil {
+		panic(err)
+	}
+	state := instance.State.Name
+	fmt.Printf("Instance ID: %s\nState: %s\n", *instance.InstanceId, state)
+
+	// 変更先を取得
+	var target types.InstanceStateName
+	if state == "running" {
+		target = "stopped"
+	} else if state == "stopped" {
+		target = "running"
+	} else {
+		return nil
+	}
+
+	// 状態変更の確認
+	fmt.Printf("Change the state to \"%s\"?(Yn): ", target)
+	sc.Scan()
+	if strings.ToLower(strings.TrimRight(sc.Text(), "\n")) == "n" {
+		return nil
+	}
+
+	// 状態を変更
+	fmt.Printf("Changing the state to %s\n", target)
+	if state == "running" {
+		awsec2.StopInstance(context.TODO(), client, profile.InstanceID)
+	} else if state == "stopped" {
+		awsec2.StartInstance(context.TODO(), client, profile.InstanceID)
+	}
+
+	// 変更先の状態になるまで待つ
+	timeout, err := profile.WaitTimeoutDuration()
+	if err != nil {
+		return fmt.Errorf("invalid wait_timeout: %w", err)
+	}
+	if err := awsec2.WaitForState(context.TODO(), client, profile.InstanceID, target, timeout); err != nil {
+		return err
+	}
+
+	instance, err = awsec2.GetInstances(context.TODO(), client, profile.InstanceID)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("Instance ID: %s\nState: %s\n", *instance.InstanceId, instance.State.Name)
+
+	// 状態がrunningであれば.ssh/configを更新し、接続メッセージを出力する。
+	// Hostブロックはprofile.Nameで識別するため、複数プロファイルが
+	// 同じ~/.ssh/configに共存できる
+	if target == "running" {
+		sconfp := fmt.Sprintf("%s/.ssh/config", home)
+		block := sshconfig.HostBlock{
+			Name:         profile.Name,
+			User:         profile.User,
+			HostName:     *instance.PublicIpAddress,
+			Port:         profile.Port,
+			IdentityFile: profile.Credential,
+		}
+		if err := sshconfig.Write(sconfp, profile.Name, block); err != nil {
+			return fmt.Errorf("failed to update ~/.ssh/config: %w", err)
+		}
+
+		fmt.Printf("Run below command to connect vscode:\ncode --remote ssh-remote+%s\n", profile.Name)
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+// Package config はec2devの設定ファイル(~/.ec2dev/config.yml)の読み書きを担う
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultWaitTimeout はwait_timeoutが設定されていない場合に使うタイムアウト
+const DefaultWaitTimeout = 5 * time.Minute
+
+// BlockDeviceMapping はプロファイルに紐づくブロックデバイス設定
+type BlockDeviceMapping struct {
+	DeviceName string `yaml:"device_name"`
+	VolumeSize int32  `yaml:"volume_size"`
+	VolumeType string `yaml:"volume_type"`
+}
+
+// Profile は1つの開発インスタンスに対応する設定
+type Profile struct {
+	InstanceID          string               `yaml:"instance_id"`
+	Region              string               `yaml:"region"`
+	Name                string               `yaml:"name"`
+	Credential          string               `yaml:"credential"`
+	Port                string               `yaml:"port"`
+	User                string               `yaml:"user"`
+	AMI                 string               `yaml:"ami"`
+	InstanceType        string               `yaml:"instance_type"`
+	Subnet              string               `yaml:"subnet"`
+	SecurityGroup       string               `yaml:"security_group"`
+	IAMInstanceProfile  string               `yaml:"iam_instance_profile"`
+	KeyPair             string               `yaml:"key_pair"`
+	UserDataFile        string               `yaml:"user_data_file"`
+	BlockDeviceMappings []BlockDeviceMapping `yaml:"block_device_mappings"`
+	AWSProfile          string               `yaml:"aws_profile"`
+	AssumeRoleARN       string               `yaml:"assume_role_arn"`
+	MFASerial           string               `yaml:"mfa_serial"`
+	WaitTimeout         string               `yaml:"wait_timeout"`
+}
+
+// WaitTimeoutDuration はWaitTimeoutを time.Duration に変換する。
+// 未設定の場合はDefaultWaitTimeoutを返す
+func (p Profile) WaitTimeoutDuration() (time.Duration, error) {
+	if p.WaitTimeout == "" {
+		return DefaultWaitTimeout, nil
+	}
+	return time.ParseDuration(p.WaitTimeout)
+}
+
+// Config は~/.ec2dev/config.ymlの中身そのもの
+type Config struct {
+	Default  string             `yaml:"default"`
+	Profiles map[string]Profile `yaml:"profiles"`
+}
+
+func path(home string) string {
+	return fmt.Sprintf("%s/.ec2dev/config.yml", home)
+}
+
+// Load は設定ファイルを読み込む
+func Load(home string) (Config, error) {
+	cfg := Config{}
+	b, err := ioutil.ReadFile(path(home))
+	if err != nil {
+		return cfg, err
+	}
+	if err := yaml.Unmarshal(b, &cfg); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+// Save は設定ファイルに書き戻す
+func Save(home string, cfg Config) error {
+	b, err := yaml.Marshal(&cfg)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path(home)), 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path(home), b, 0600)
+}
+
+// Resolve は--profileで指定された名前、もしくはdefaultキーで指定された
+// プロファイルを名前と合わせて返す
+func (c Config) Resolve(name string) (string, Profile, error) {
+	if name == "" {
+		name = c.Default
+	}
+	if name == "" {
+		return "", Profile{}, fmt.Errorf("no profile specified and no default profile configured")
+	}
+	p, ok := c.Profiles[name]
+	if !ok {
+		return "", Profile{}, fmt.Errorf("profile %q not found in config.yml", name)
+	}
+	return name, p, nil
+}
+
+// Set はnameのプロファイルを更新する
+func (c *Config) Set(name string, p Profile) {
+	if c.Profiles == nil {
+		c.Profiles = map[string]Profile{}
+	}
+	c.Profiles[name] = p
+}
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeConfig(t *testing.T, home, contents string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(home, ".ec2dev"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path(home), []byte(contents), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadAndResolve(t *testing.T) {
+	home := t.TempDir()
+	writeConfig(t, home, `
+default: dev
+profiles:
+  dev:
+    instance_id: i-dev
+    name: dev-box
+  gpu:
+    instance_id: i-gpu
+    name: gpu-box
+`)
+
+	cfg, err := Load(home)
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+
+	name, profile, err := cfg.Resolve("")
+	if err != nil {
+		t.Fatalf("Resolve(\"\") returned error: %v", err)
+	}
+	if name != "dev" || profile.InstanceID != "i-dev" {
+		t.Fatalf("Resolve(\"\") = %q, %+v, want dev profile", name, profile)
+	}
+
+	name, profile, err = cfg.Resolve("gpu")
+	if err != nil {
+		t.Fatalf("Resolve(\"gpu\") returned error: %v", err)
+	}
+	if name != "gpu" || profile.InstanceID != "i-gpu" {
+		t.Fatalf("Resolve(\"gpu\") = %q, %+v, want gpu profile", name, profile)
+	}
+
+	if _, _, err := cfg.Resolve("missing"); err == nil {
+		t.Fatal("Resolve(\"missing\") should return an error")
+	}
+}
+
+func TestLoadAssumeRoleFields(t *testing.T) {
+	home := t.TempDir()
+	writeConfig(t, home, `
+default: dev
+profiles:
+  dev:
+    instance_id: i-dev
+    aws_profile: sso-dev
+    assume_role_arn: arn:aws:iam::123456789012:role/ec2dev
+    mfa_serial: arn:aws:iam::123456789012:mfa/alice
+`)
+
+	cfg, err := Load(home)
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+
+	_, profile, err := cfg.Resolve("")
+	if err != nil {
+		t.Fatalf("Resolve(\"\") returned error: %v", err)
+	}
+	if profile.AWSProfile != "sso-dev" {
+		t.Fatalf("AWSProfile = %q, want sso-dev", profile.AWSProfile)
+	}
+	if profile.AssumeRoleARN != "arn:aws:iam::123456789012:role/ec2dev" {
+		t.Fatalf("AssumeRoleARN = %q, want role ARN", profile.AssumeRoleARN)
+	}
+	if profile.MFASerial != "arn:aws:iam::123456789012:mfa/alice" {
+		t.Fatalf("MFASerial = %q, want mfa ARN", profile.MFASerial)
+	}
+}
+
+func TestResolveNoDefault(t *testing.T) {
+	cfg := Config{Profiles: map[string]Profile{"dev": {}}}
+	if _, _, err := cfg.Resolve(""); err == nil {
+		t.Fatal("Resolve(\"\") should error when no profile and no default are set")
+	}
+}
+
+func TestSaveRoundTrip(t *testing.T) {
+	home := t.TempDir()
+	cfg := Config{Default: "dev"}
+	cfg.Set("dev", Profile{InstanceID: "i-123", Name: "dev-box"})
+
+	if err := Save(home, cfg); err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+
+	got, err := Load(home)
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	_, profile, err := got.Resolve("")
+	if err != nil {
+		t.Fatalf("Resolve(\"\") returned error: %v", err)
+	}
+	if profile.InstanceID != "i-123" {
+		t.Fatalf("InstanceID = %q, want i-123", profile.InstanceID)
+	}
+}
@@ -0,0 +1,104 @@
+// Package sshconfig manages a single ec2dev-owned Host block inside
+// ~/.ssh/config without disturbing anything else in the file.
+package sshconfig
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func beginMarker(name string) string { return fmt.Sprintf("# BEGIN ec2dev:%s", name) }
+func endMarker(name string) string   { return fmt.Sprintf("# END ec2dev:%s", name) }
+
+// HostBlock is the managed content for a single profile's Host entry.
+type HostBlock struct {
+	Name         string
+	User         string
+	HostName     string
+	Port         string
+	IdentityFile string
+}
+
+// Render formats the Host stanza for the block, without the surrounding markers.
+func (b HostBlock) Render() string {
+	return fmt.Sprintf("Host %s\n  User %s\n  HostName %s\n  LocalForward %s localhost:%s\n  IdentityFile %s\n  ServerAliveInterval 5\n  ExitOnForwardFailure yes\n",
+		b.Name, b.User, b.HostName, b.Port, b.Port, b.IdentityFile)
+}
+
+// Upsert replaces the ec2dev-managed block for name inside contents, or
+// appends it at the end if no such block exists yet. Everything outside the
+// markers - Match blocks, indented Host directives, comments, other Hosts -
+// is left untouched. The rewrite is scoped purely to the sentinel markers,
+// so it never has to parse the rest of the file: upstream ssh_config parsers
+// (including kevinburke/ssh_config) don't support the Match directive, and a
+// full-file round-trip would fail on exactly the configs this is meant to
+// handle safely.
+func Upsert(contents, name, body string) string {
+	begin, end := beginMarker(name), endMarker(name)
+	managed := strings.TrimRight(fmt.Sprintf("%s\n%s%s", begin, body, end), "\n")
+
+	var out []string
+	inBlock := false
+	found := false
+	for _, line := range strings.Split(contents, "\n") {
+		switch strings.TrimSpace(line) {
+		case begin:
+			inBlock = true
+			found = true
+			out = append(out, managed)
+			continue
+		case end:
+			inBlock = false
+			continue
+		}
+		if inBlock {
+			continue
+		}
+		out = append(out, line)
+	}
+
+	result := strings.Join(out, "\n")
+	if !found {
+		if result != "" && !strings.HasSuffix(result, "\n") {
+			result += "\n"
+		}
+		result += managed + "\n"
+	}
+
+	return result
+}
+
+// Write upserts the Host block for name into the ssh config at path, then
+// writes the result atomically (tempfile + rename) with 0600 permissions -
+// ~/.ssh/config must never be group/world readable.
+func Write(path, name string, block HostBlock) error {
+	var existing []byte
+	if b, err := ioutil.ReadFile(path); err == nil {
+		existing = b
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	updated := Upsert(string(existing), name, block.Render())
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), ".ec2dev-ssh-config-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.WriteString(updated); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
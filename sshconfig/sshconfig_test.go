@@ -0,0 +1,87 @@
+package sshconfig
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUpsertAppendsWhenAbsent(t *testing.T) {
+	existing := "Host bastion\n  User ec2-user\n  HostName example.com\n"
+
+	out := Upsert(existing, "dev", "Host dev\n  User ubuntu\n  HostName 1.2.3.4\n")
+	if !strings.Contains(out, "Host bastion") {
+		t.Fatalf("existing Host block was dropped:\n%s", out)
+	}
+	if !strings.Contains(out, "# BEGIN ec2dev:dev") || !strings.Contains(out, "# END ec2dev:dev") {
+		t.Fatalf("missing sentinel markers:\n%s", out)
+	}
+	if !strings.Contains(out, "HostName 1.2.3.4") {
+		t.Fatalf("new Host block was not appended:\n%s", out)
+	}
+}
+
+func TestUpsertReplacesExistingBlock(t *testing.T) {
+	existing := strings.Join([]string{
+		"Host bastion",
+		"  User ec2-user",
+		"",
+		"# BEGIN ec2dev:dev",
+		"Host dev",
+		"  HostName 1.1.1.1",
+		"# END ec2dev:dev",
+		"",
+		"Host other",
+		"  User root",
+		"",
+	}, "\n")
+
+	out := Upsert(existing, "dev", "Host dev\n  HostName 2.2.2.2\n")
+	if strings.Contains(out, "1.1.1.1") {
+		t.Fatalf("stale Host block was not replaced:\n%s", out)
+	}
+	if !strings.Contains(out, "2.2.2.2") {
+		t.Fatalf("updated Host block is missing:\n%s", out)
+	}
+	if !strings.Contains(out, "Host bastion") || !strings.Contains(out, "Host other") {
+		t.Fatalf("unrelated Host blocks were disturbed:\n%s", out)
+	}
+}
+
+func TestUpsertPreservesMatchAndComments(t *testing.T) {
+	existing := strings.Join([]string{
+		"# a comment that mentions Host in passing",
+		"Match host *.internal",
+		"  User internal-user",
+		"",
+		"Host dev",
+		"  indented continuation is fine too",
+	}, "\n")
+
+	out := Upsert(existing, "dev", "Host dev\n  HostName 3.3.3.3\n")
+	if !strings.Contains(out, "Match host *.internal") {
+		t.Fatalf("Match block was dropped:\n%s", out)
+	}
+	if !strings.Contains(out, "# a comment that mentions Host in passing") {
+		t.Fatalf("comment was dropped:\n%s", out)
+	}
+}
+
+func TestWriteIsAtomicAndRestrictsPerms(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config")
+
+	block := HostBlock{Name: "dev", User: "ubuntu", HostName: "1.2.3.4", Port: "2222", IdentityFile: "~/.ssh/id_ed25519"}
+	if err := Write(path, "dev", block); err != nil {
+		t.Fatalf("Write returned error: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat returned error: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Fatalf("permissions = %o, want 0600", perm)
+	}
+}